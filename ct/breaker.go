@@ -0,0 +1,60 @@
+package ct
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned when a log's circuit breaker has tripped and is
+// still in its cool-down window.
+var ErrCircuitOpen = errors.New("ct: circuit breaker open for this log")
+
+// breaker trips a log out of rotation after threshold consecutive failures,
+// and keeps it out until cooldown has elapsed since the last failure.
+// A zero-value breaker (threshold <= 0) never trips.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu          sync.Mutex
+	failures    int
+	openedUntil time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call should be attempted, i.e. the breaker is
+// disabled, closed, or its cool-down window has elapsed.
+func (b *breaker) Allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures < b.threshold || !time.Now().Before(b.openedUntil)
+}
+
+// RecordSuccess resets the failure count, closing the breaker.
+func (b *breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// RecordFailure counts a failure, tripping the breaker once threshold is
+// reached and resetting the cool-down window.
+func (b *breaker) RecordFailure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,56 @@
+package ct
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerDisabledWhenThresholdNotPositive(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.RecordFailure()
+	}
+	if !b.Allow() {
+		t.Error("expected a zero threshold to never trip")
+	}
+}
+
+func TestBreakerTripsAfterThreshold(t *testing.T) {
+	b := newBreaker(3, time.Hour)
+
+	b.RecordFailure()
+	b.RecordFailure()
+	if !b.Allow() {
+		t.Error("breaker should stay closed before reaching the threshold")
+	}
+
+	b.RecordFailure()
+	if b.Allow() {
+		t.Error("breaker should trip once the threshold is reached")
+	}
+}
+
+func TestBreakerRecoversAfterCooldown(t *testing.T) {
+	b := newBreaker(1, 10*time.Millisecond)
+
+	b.RecordFailure()
+	if b.Allow() {
+		t.Fatal("breaker should be open immediately after tripping")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.Allow() {
+		t.Error("breaker should allow a call again once the cool-down elapses")
+	}
+}
+
+func TestBreakerSuccessResetsFailureCount(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+
+	b.RecordFailure()
+	b.RecordSuccess()
+	b.RecordFailure()
+	if !b.Allow() {
+		t.Error("a success should reset the failure count, so one more failure shouldn't trip the breaker")
+	}
+}
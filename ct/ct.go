@@ -14,6 +14,7 @@ import (
 
 	ct "github.com/google/certificate-transparency-go"
 	"github.com/google/certificate-transparency-go/client"
+	"github.com/google/certificate-transparency-go/ctutil"
 	"github.com/google/certificate-transparency-go/jsonclient"
 	cttls "github.com/google/certificate-transparency-go/tls"
 	ctx509 "github.com/google/certificate-transparency-go/x509"
@@ -29,6 +30,24 @@ var (
 type Config struct {
 	URI string `json:"uri"`
 	Key string `json:"key"`
+
+	// Timeout bounds a single GetSCTs or SubmitToLogs call to the log,
+	// including all of its retries and backoff delays. Defaults to 30s.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// MaxRetries is the number of additional attempts made after a
+	// transient failure before giving up. Defaults to 3.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// InitialBackoff is the delay before the first retry, doubling (with
+	// jitter) on each subsequent attempt up to MaxBackoff. Defaults to
+	// 500ms.
+	InitialBackoff time.Duration `json:"initialBackoff,omitempty"`
+	// MaxBackoff caps the retry delay. Defaults to 10s.
+	MaxBackoff time.Duration `json:"maxBackoff,omitempty"`
+	// BreakerThreshold is the number of consecutive failed calls, after
+	// retries are exhausted, that trips this log out of rotation for a
+	// cool-down window equal to MaxBackoff. Defaults to 5. A negative value
+	// disables the breaker.
+	BreakerThreshold int `json:"breakerThreshold,omitempty"`
 }
 
 // Validate validates the ct configuration.
@@ -43,15 +62,46 @@ func (c *Config) Validate() error {
 	}
 }
 
+const (
+	defaultTimeout          = 30 * time.Second
+	defaultMaxRetries       = 3
+	defaultInitialBackoff   = 500 * time.Millisecond
+	defaultMaxBackoff       = 10 * time.Second
+	defaultBreakerThreshold = 5
+)
+
+// withDefaults returns a copy of c with zero-valued fields set to their
+// defaults.
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+	if c.BreakerThreshold == 0 {
+		c.BreakerThreshold = defaultBreakerThreshold
+	}
+	return c
+}
+
 // Client is the interfaced used to communicate with the certificate transparency logs.
 type Client interface {
 	GetSCTs(chain ...*x509.Certificate) (*SCT, error)
 	SubmitToLogs(chain ...*x509.Certificate) error
+	Verify(chain []*x509.Certificate, sct *SCT) error
 }
 
 type logClient interface {
 	AddPreChain(ctx context.Context, chain []ct.ASN1Cert) (*ct.SignedCertificateTimestamp, error)
 	AddChain(ctx context.Context, chain []ct.ASN1Cert) (*ct.SignedCertificateTimestamp, error)
+	GetSTH(ctx context.Context) (*ct.SignedTreeHead, error)
 }
 
 // SCT represents a Signed Certificate Timestamp.
@@ -62,28 +112,60 @@ type SCT struct {
 
 // GetExtension returns the extension representing an SCT that will be added to
 // a certificate.
+//
+// Deprecated: use NewSCTListExtension to build the extension for one or more
+// SCTs, e.g. when SCTs were gathered from multiple logs using a Pool.
 func (t *SCT) GetExtension() pkix.Extension {
-	val, err := cttls.Marshal(*t.SCT)
+	ext, err := NewSCTListExtension([]*SCT{t})
 	if err != nil {
 		panic(err)
 	}
-	value, err := cttls.Marshal(ctx509.SignedCertificateTimestampList{
-		SCTList: []ctx509.SerializedSCT{
-			{Val: val},
-		},
-	})
+	return ext
+}
+
+// NewSCTListExtension marshals scts into the X.509 extension used to embed a
+// SignedCertificateTimestampList in a certificate.
+func NewSCTListExtension(scts []*SCT) (pkix.Extension, error) {
+	list := ctx509.SignedCertificateTimestampList{}
+	for _, t := range scts {
+		val, err := cttls.Marshal(*t.SCT)
+		if err != nil {
+			return pkix.Extension{}, errors.Wrapf(err, "failed to marshal SCT from %s", t.LogURL)
+		}
+		list.SCTList = append(list.SCTList, ctx509.SerializedSCT{Val: val})
+	}
+	value, err := cttls.Marshal(list)
 	if err != nil {
-		panic(err)
+		return pkix.Extension{}, errors.Wrap(err, "failed to marshal SignedCertificateTimestampList")
 	}
 	rawValue, err := asn1.Marshal(value)
 	if err != nil {
-		panic(err)
+		return pkix.Extension{}, errors.Wrap(err, "failed to marshal SignedCertificateTimestampList")
 	}
 	return pkix.Extension{
 		Id:       oidSignedCertificateTimestampList,
 		Critical: false,
 		Value:    rawValue,
-	}
+	}, nil
+}
+
+// ErrSCTSignatureInvalid is returned when a log returns an SCT whose
+// signature does not verify against the log's own public key. Callers should
+// treat the SCT as untrustworthy and must not embed it in an issued
+// certificate.
+type ErrSCTSignatureInvalid struct {
+	LogURL string
+	LogID  ct.SHA256Hash
+	Err    error
+}
+
+func (e *ErrSCTSignatureInvalid) Error() string {
+	return fmt.Sprintf("invalid SCT signature from log %s (logID %x): %v", e.LogURL, e.LogID, e.Err)
+}
+
+// Unwrap returns the underlying verification error.
+func (e *ErrSCTSignatureInvalid) Unwrap() error {
+	return e.Err
 }
 
 // AddPoisonExtension appends the ct poison extension to the given certificate.
@@ -98,7 +180,8 @@ func AddPoisonExtension(cert *x509.Certificate) {
 type ClientImpl struct {
 	config    Config
 	logClient logClient
-	timeout   time.Duration
+	verifier  *ct.SignatureVerifier
+	breaker   *breaker
 }
 
 // New creates a new Client
@@ -113,55 +196,150 @@ func New(c Config) (*ClientImpl, error) {
 		return nil, errors.Wrapf(err, "invalid public key %s", c.Key)
 	}
 
+	ci, err := newClient(c, block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.withDefaults().Timeout)
+	defer cancel()
+	if _, err := ci.logClient.GetSTH(ctx); err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", c.URI)
+	}
+	log.Printf("connecting to CT log %s", c.URI)
+	return ci, nil
+}
+
+// newClient creates a ClientImpl for the log described by c, authenticating
+// its responses with keyDER, the DER-encoded SubjectPublicKeyInfo of the
+// log. It is used directly by New, and by Pool to build a client per log
+// listed in a log-list file. Unlike New, it does not contact the log: Pool
+// builds one client per log in a list that can be dozens of entries long,
+// and a single slow or unreachable log shouldn't block construction of the
+// whole Pool. Reachability of each log is instead handled at submission
+// time by its circuit breaker.
+func newClient(c Config, keyDER []byte) (*ClientImpl, error) {
+	c = c.withDefaults()
+
+	// Build a verifier for the log's own signatures so SCTs it returns can be
+	// checked before they're trusted.
+	pubKey, err := x509.ParsePKIXPublicKey(keyDER)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid public key for %s", c.URI)
+	}
+	verifier, err := ct.NewSignatureVerifier(pubKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build signature verifier for %s", c.URI)
+	}
+
 	// Initialize ct client
 	logClient, err := client.New(c.URI, &http.Client{}, jsonclient.Options{
-		PublicKeyDER: block.Bytes,
+		PublicKeyDER: keyDER,
 		UserAgent:    "smallstep certificates",
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create client to %s", c.URI)
 	}
 
-	// Validate connection
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if _, err := logClient.GetSTH(ctx); err != nil {
-		return nil, errors.Wrapf(err, "failed to connect to %s", c.URI)
-	}
-	log.Printf("connecting to CT log %s", c.URI)
-
 	return &ClientImpl{
 		config:    c,
 		logClient: logClient,
-		timeout:   30 * time.Second,
+		verifier:  verifier,
+		breaker:   newBreaker(c.BreakerThreshold, c.MaxBackoff),
 	}, nil
 }
 
 // GetSCTs submit the precertificate to the logs and returns the list of SCTs to
 // embed into the certificate.
 func (c *ClientImpl) GetSCTs(chain ...*x509.Certificate) (*SCT, error) {
+	if !c.breaker.Allow() {
+		return nil, errors.Wrapf(ErrCircuitOpen, "log %s", c.config.URI)
+	}
+
 	ctChain := chainFromCerts(chain)
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
 	defer cancel()
-	sct, err := c.logClient.AddPreChain(ctx, ctChain)
+
+	var sct *ct.SignedCertificateTimestamp
+	err := withRetry(ctx, c.config.MaxRetries, c.config.InitialBackoff, c.config.MaxBackoff, func() (err error) {
+		sct, err = c.logClient.AddPreChain(ctx, ctChain)
+		return err
+	})
 	if err != nil {
+		c.breaker.RecordFailure()
 		return nil, errors.Wrapf(err, "failed to get SCT from %s", c.config.URI)
 	}
-	return &SCT{
+
+	result := &SCT{
 		LogURL: c.config.URI,
 		SCT:    sct,
-	}, nil
+	}
+	if err := c.verifySCT(chain, sct); err != nil {
+		c.breaker.RecordFailure()
+		return nil, err
+	}
+	c.breaker.RecordSuccess()
+	return result, nil
+}
+
+// Verify checks that sct is a validly signed timestamp over chain, as issued
+// by this log. It can be used to (re-)validate SCTs obtained from a source
+// other than GetSCTs, e.g. ones extracted from an already-issued certificate.
+func (c *ClientImpl) Verify(chain []*x509.Certificate, sct *SCT) error {
+	return c.verifySCT(chain, sct.SCT)
+}
+
+// verifySCT checks sct's signature against chain using this log's public
+// key, i.e. the same check the log itself must have performed when it
+// countersigned the precertificate's TBS and issuer key hash.
+func (c *ClientImpl) verifySCT(chain []*x509.Certificate, sct *ct.SignedCertificateTimestamp) error {
+	ctChain, err := ctx509Chain(chain)
+	if err != nil {
+		return err
+	}
+	if err := ctutil.VerifySCTWithVerifier(c.verifier, ctChain, sct, false); err != nil {
+		return &ErrSCTSignatureInvalid{
+			LogURL: c.config.URI,
+			LogID:  sct.LogID.KeyID,
+			Err:    err,
+		}
+	}
+	return nil
+}
+
+// ctx509Chain re-parses chain's raw DER with the ctx509 package, which
+// ctutil needs in order to recognize and verify SCTs over precertificates.
+func ctx509Chain(chain []*x509.Certificate) ([]*ctx509.Certificate, error) {
+	out := make([]*ctx509.Certificate, len(chain))
+	for i, cert := range chain {
+		parsed, err := ctx509.ParseCertificate(cert.Raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		out[i] = parsed
+	}
+	return out, nil
 }
 
 // SubmitToLogs submits the certificate to the certificate transparency logs.
 func (c *ClientImpl) SubmitToLogs(chain ...*x509.Certificate) error {
+	if !c.breaker.Allow() {
+		return errors.Wrapf(ErrCircuitOpen, "log %s", c.config.URI)
+	}
+
 	ctChain := chainFromCerts(chain)
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
 	defer cancel()
-	sct, err := c.logClient.AddChain(ctx, ctChain)
+
+	var sct *ct.SignedCertificateTimestamp
+	err := withRetry(ctx, c.config.MaxRetries, c.config.InitialBackoff, c.config.MaxBackoff, func() (err error) {
+		sct, err = c.logClient.AddChain(ctx, ctChain)
+		return err
+	})
 	if err != nil {
+		c.breaker.RecordFailure()
 		return errors.Wrapf(err, "failed submit certificate to %s", c.config.URI)
 	}
+	c.breaker.RecordSuccess()
 
 	// Calculate the leaf hash
 	leafEntry := ct.CreateX509MerkleTreeLeaf(ctChain[0], sct.Timestamp)
@@ -0,0 +1,83 @@
+package ct
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TemporalInterval bounds the NotAfter date of certificates that a log will
+// accept, as published by browser vendor log lists so that logs approaching
+// their shard boundary aren't sent certificates outside of their window.
+type TemporalInterval struct {
+	StartInclusive time.Time `json:"start_inclusive"`
+	EndExclusive   time.Time `json:"end_exclusive"`
+}
+
+// contains reports whether t falls within the interval.
+func (i *TemporalInterval) contains(t time.Time) bool {
+	return !t.Before(i.StartInclusive) && t.Before(i.EndExclusive)
+}
+
+// LogInfo describes a single CT log entry in a LogList.
+type LogInfo struct {
+	URL string `json:"url"`
+	// Key is the base64-encoded DER SubjectPublicKeyInfo of the log.
+	Key string `json:"key"`
+	// TemporalInterval restricts submission to certificates whose NotAfter
+	// date falls within the interval. A nil interval accepts any NotAfter.
+	TemporalInterval *TemporalInterval `json:"temporal_interval,omitempty"`
+	// Operator is set from the name of the LogOperator that owns this log.
+	Operator string `json:"-"`
+}
+
+// keyDER decodes the base64-encoded public key of the log.
+func (l *LogInfo) keyDER() ([]byte, error) {
+	der, err := base64.StdEncoding.DecodeString(l.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid public key for log %s", l.URL)
+	}
+	return der, nil
+}
+
+// LogOperator groups the logs run by a single CT log operator, as used by
+// the log lists published at https://www.gstatic.com/ct/log_list/v3/log_list.json.
+type LogOperator struct {
+	Name string    `json:"name"`
+	Logs []LogInfo `json:"logs"`
+}
+
+// LogList is a list of CT logs grouped by operator, in the format used by
+// browser vendors to distribute the set of logs they trust.
+type LogList struct {
+	Operators []LogOperator `json:"operators"`
+}
+
+// Logs flattens the log list into a single slice, with each LogInfo's
+// Operator field set to the name of the operator that owns it.
+func (l *LogList) Logs() []LogInfo {
+	var logs []LogInfo
+	for _, op := range l.Operators {
+		for _, li := range op.Logs {
+			li.Operator = op.Name
+			logs = append(logs, li)
+		}
+	}
+	return logs
+}
+
+// LoadLogList reads and parses a log-list JSON file.
+func LoadLogList(file string) (*LogList, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", file)
+	}
+	var list LogList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", file)
+	}
+	return &list, nil
+}
@@ -0,0 +1,69 @@
+package ct
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemporalIntervalContains(t *testing.T) {
+	interval := &TemporalInterval{
+		StartInclusive: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndExclusive:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"start is inclusive", interval.StartInclusive, true},
+		{"end is exclusive", interval.EndExclusive, false},
+		{"inside window", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), true},
+		{"before window", time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), false},
+		{"after window", time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := interval.contains(tc.t); got != tc.want {
+				t.Errorf("contains(%v) = %v, want %v", tc.t, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLogListLogs(t *testing.T) {
+	list := &LogList{
+		Operators: []LogOperator{
+			{
+				Name: "Google",
+				Logs: []LogInfo{{URL: "https://ct.googleapis.com/logs/argon2024"}},
+			},
+			{
+				Name: "Cloudflare",
+				Logs: []LogInfo{
+					{URL: "https://ct.cloudflare.com/logs/nimbus2024"},
+					{URL: "https://ct.cloudflare.com/logs/nimbus2025"},
+				},
+			},
+		},
+	}
+
+	logs := list.Logs()
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logs, got %d", len(logs))
+	}
+	for _, li := range logs {
+		switch li.URL {
+		case "https://ct.googleapis.com/logs/argon2024":
+			if li.Operator != "Google" {
+				t.Errorf("expected operator Google, got %s", li.Operator)
+			}
+		case "https://ct.cloudflare.com/logs/nimbus2024", "https://ct.cloudflare.com/logs/nimbus2025":
+			if li.Operator != "Cloudflare" {
+				t.Errorf("expected operator Cloudflare, got %s", li.Operator)
+			}
+		default:
+			t.Errorf("unexpected log URL %s", li.URL)
+		}
+	}
+}
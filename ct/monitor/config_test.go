@@ -0,0 +1,49 @@
+package monitor
+
+import "testing"
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{
+			name:    "no logs",
+			config:  Config{},
+			wantErr: true,
+		},
+		{
+			name: "log missing url",
+			config: Config{
+				Logs: []LogConfig{{Key: "/path/to/key.pem"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "log missing key",
+			config: Config{
+				Logs: []LogConfig{{URL: "https://ct.example.com/log"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid",
+			config: Config{
+				Logs: []LogConfig{{URL: "https://ct.example.com/log", Key: "/path/to/key.pem"}},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr && err == nil {
+				t.Error("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
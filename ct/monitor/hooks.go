@@ -0,0 +1,39 @@
+package monitor
+
+import "time"
+
+// Hooks lets operators observe monitor events, typically by recording them
+// as Prometheus-style metrics. All methods are called synchronously from the
+// monitor's polling loop, so implementations should not block.
+type Hooks interface {
+	// STHAge is called after every successful poll of a log with the age of
+	// the STH the log returned.
+	STHAge(logURL string, age time.Duration)
+	// ConsistencyFailure is called when a log's new STH cannot be shown to be
+	// consistent with the previously observed STH: either the consistency
+	// proof could not be fetched from the log, or it was fetched and does not
+	// verify, i.e. the log rewrote history.
+	ConsistencyFailure(logURL string, err error)
+	// MissingInclusion is called when a certificate submitted to a log is
+	// still absent after the log's Maximum Merge Delay has elapsed.
+	MissingInclusion(logURL string, leafHash [32]byte, submittedAt time.Time)
+	// MMDViolation is called when a certificate is eventually found in the
+	// log, but only after its Maximum Merge Delay deadline had passed.
+	MMDViolation(logURL string, leafHash [32]byte, deadline time.Time)
+}
+
+// NoopHooks implements Hooks by discarding every event. It is the default
+// used when a Monitor is created without hooks of its own.
+type NoopHooks struct{}
+
+// STHAge implements Hooks.
+func (NoopHooks) STHAge(string, time.Duration) {}
+
+// ConsistencyFailure implements Hooks.
+func (NoopHooks) ConsistencyFailure(string, error) {}
+
+// MissingInclusion implements Hooks.
+func (NoopHooks) MissingInclusion(string, [32]byte, time.Time) {}
+
+// MMDViolation implements Hooks.
+func (NoopHooks) MMDViolation(string, [32]byte, time.Time) {}
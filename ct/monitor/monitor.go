@@ -0,0 +1,314 @@
+// Package monitor implements a background auditor for certificate
+// transparency logs. It periodically fetches each configured log's Signed
+// Tree Head (STH), checks that it is consistent with the previous one, and
+// confirms that certificates this CA submitted are actually merged into the
+// log within its Maximum Merge Delay (MMD). This lets a CA detect a
+// misbehaving or dishonest log instead of silently trusting it.
+package monitor
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/client"
+	"github.com/google/certificate-transparency-go/jsonclient"
+	"github.com/pkg/errors"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// LogConfig describes a single log to monitor.
+type LogConfig struct {
+	URL string `json:"url"`
+	// Key is the path to the log's PEM-encoded public key, used to verify
+	// STH signatures.
+	Key string `json:"key"`
+	// MMD is the log's Maximum Merge Delay: the longest a submitted
+	// certificate can take to appear in the log before it's reported as a
+	// missing inclusion.
+	MMD time.Duration `json:"mmd"`
+}
+
+// Config configures a Monitor.
+type Config struct {
+	Logs []LogConfig `json:"logs"`
+	// Interval is how often each log is polled for a fresh STH. Defaults to
+	// 5 minutes.
+	Interval time.Duration `json:"interval"`
+	// StoreDir is the directory the default file-backed Store persists
+	// observed STHs to. Ignored if Store is set on the Monitor directly.
+	StoreDir string `json:"storeDir"`
+}
+
+// Validate validates the monitor configuration.
+func (c *Config) Validate() error {
+	if len(c.Logs) == 0 {
+		return errors.New("ct monitor must be configured with at least one log")
+	}
+	for _, l := range c.Logs {
+		if l.URL == "" {
+			return errors.New("ct monitor log url cannot be empty")
+		}
+		if l.Key == "" {
+			return errors.New("ct monitor log key cannot be empty")
+		}
+	}
+	return nil
+}
+
+// pendingLeaf is a submitted certificate whose inclusion in the log has not
+// yet been confirmed.
+type pendingLeaf struct {
+	leafHash    [32]byte
+	submittedAt time.Time
+	deadline    time.Time
+}
+
+// logClient is the subset of client.LogClient that target needs, narrowed
+// down so tests can exercise pollOne and checkPending against a fake.
+type logClient interface {
+	GetSTH(ctx context.Context) (*ct.SignedTreeHead, error)
+	GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error)
+	GetProofByHash(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error)
+}
+
+// target is a single log being monitored.
+type target struct {
+	url    string
+	client logClient
+	mmd    time.Duration
+
+	mu      sync.Mutex
+	pending []pendingLeaf
+}
+
+// Monitor periodically audits a set of CT logs.
+type Monitor struct {
+	targets  []*target
+	store    Store
+	hooks    Hooks
+	interval time.Duration
+}
+
+// New creates a Monitor from c. If store is nil, a FileStore rooted at
+// c.StoreDir is used. If hooks is nil, monitor events are discarded.
+func New(c Config, store Store, hooks Hooks) (*Monitor, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if store == nil {
+		fs, err := NewFileStore(c.StoreDir)
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	}
+	if hooks == nil {
+		hooks = NoopHooks{}
+	}
+
+	interval := c.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	targets := make([]*target, len(c.Logs))
+	for i, lc := range c.Logs {
+		t, err := newTarget(lc)
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = t
+	}
+
+	return &Monitor{
+		targets:  targets,
+		store:    store,
+		hooks:    hooks,
+		interval: interval,
+	}, nil
+}
+
+func newTarget(c LogConfig) (*target, error) {
+	data, err := ioutil.ReadFile(c.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", c.Key)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("invalid public key %s", c.Key)
+	}
+
+	lc, err := client.New(c.URL, &http.Client{}, jsonclient.Options{
+		PublicKeyDER: block.Bytes,
+		UserAgent:    "smallstep certificates monitor",
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client to %s", c.URL)
+	}
+
+	return &target{
+		url:    c.URL,
+		client: lc,
+		mmd:    c.MMD,
+	}, nil
+}
+
+// TrackSubmission records that a certificate with the given leaf hash was
+// submitted to logURL, so that Run can later confirm it was merged into the
+// log within the log's MMD. It is a no-op if logURL is not being monitored.
+func (m *Monitor) TrackSubmission(logURL string, leafHash [32]byte, submittedAt time.Time) {
+	for _, t := range m.targets {
+		if t.url != logURL {
+			continue
+		}
+		t.mu.Lock()
+		t.pending = append(t.pending, pendingLeaf{
+			leafHash:    leafHash,
+			submittedAt: submittedAt,
+			deadline:    submittedAt.Add(t.mmd),
+		})
+		t.mu.Unlock()
+		return
+	}
+}
+
+// Run polls every configured log every interval until ctx is canceled.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.pollAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			m.pollAll(ctx)
+		}
+	}
+}
+
+func (m *Monitor) pollAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(len(m.targets))
+	for _, t := range m.targets {
+		go func(t *target) {
+			defer wg.Done()
+			if err := m.pollOne(ctx, t); err != nil {
+				log.Printf("ct monitor: %s: %v", t.url, err)
+			}
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (m *Monitor) pollOne(ctx context.Context, t *target) error {
+	sth, err := t.client.GetSTH(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get STH")
+	}
+	m.hooks.STHAge(t.url, time.Since(ct.TimestampToTime(sth.Timestamp)))
+
+	prev, err := m.store.Load(t.url)
+	if err != nil {
+		return errors.Wrap(err, "failed to load previous STH")
+	}
+	if prev != nil && prev.TreeSize > 0 && prev.TreeSize != sth.TreeSize {
+		consistencyProof, err := t.client.GetSTHConsistency(ctx, prev.TreeSize, sth.TreeSize)
+		if err != nil {
+			m.hooks.ConsistencyFailure(t.url, err)
+			return errors.Wrap(err, "failed to get consistency proof")
+		}
+		if err := proof.VerifyConsistency(
+			rfc6962.DefaultHasher,
+			prev.TreeSize, sth.TreeSize,
+			consistencyProof,
+			prev.RootHash, sth.SHA256RootHash[:],
+		); err != nil {
+			m.hooks.ConsistencyFailure(t.url, err)
+			return errors.Wrap(err, "consistency proof does not verify")
+		}
+	}
+
+	if err := m.store.Save(t.url, &StoredSTH{
+		TreeSize:  sth.TreeSize,
+		Timestamp: sth.Timestamp,
+		RootHash:  sth.SHA256RootHash[:],
+	}); err != nil {
+		return errors.Wrap(err, "failed to save STH")
+	}
+
+	m.checkPending(ctx, t, sth.TreeSize)
+	return nil
+}
+
+// checkPending looks for inclusion proofs for leaves submitted to t, firing
+// MissingInclusion for anything past its MMD deadline that still isn't
+// merged, and MMDViolation for anything that merged, but too late.
+func (m *Monitor) checkPending(ctx context.Context, t *target, treeSize uint64) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	var stillPending []pendingLeaf
+	for _, p := range pending {
+		_, err := t.client.GetProofByHash(ctx, p.leafHash[:], treeSize)
+		switch {
+		case err == nil:
+			if time.Now().After(p.deadline) {
+				m.hooks.MMDViolation(t.url, p.leafHash, p.deadline)
+			}
+		case !isProofNotFound(err):
+			// A transport/fetch error tells us nothing about whether the leaf
+			// is actually included; retry on the next poll instead of letting
+			// it count toward the deadline.
+			log.Printf("ct monitor: %s: failed to fetch inclusion proof, will retry: %v", t.url, err)
+			stillPending = append(stillPending, p)
+		case time.Now().After(p.deadline):
+			m.hooks.MissingInclusion(t.url, p.leafHash, p.submittedAt)
+		default:
+			stillPending = append(stillPending, p)
+		}
+	}
+
+	t.mu.Lock()
+	t.pending = append(t.pending, stillPending...)
+	t.mu.Unlock()
+}
+
+// isProofNotFound reports whether err is the log telling us it has no
+// inclusion proof for the requested hash, as opposed to a transport or
+// server-side failure that doesn't actually tell us whether the leaf is
+// included. The log API returns a 4xx status for the former.
+func isProofNotFound(err error) bool {
+	var rspErr jsonclient.RspError
+	if errors.As(err, &rspErr) {
+		return rspErr.StatusCode >= 400 && rspErr.StatusCode < 500
+	}
+	return false
+}
+
+// LeafHash computes the Merkle leaf hash for chain[0] as it would appear in
+// a log's tree once merged, for use with TrackSubmission.
+func LeafHash(chain []*x509.Certificate, timestamp uint64) ([32]byte, error) {
+	var asn1Chain []ct.ASN1Cert
+	for _, c := range chain {
+		asn1Chain = append(asn1Chain, ct.ASN1Cert{Data: c.Raw})
+	}
+	leaf := ct.CreateX509MerkleTreeLeaf(asn1Chain[0], timestamp)
+	hash, err := ct.LeafHashForLeaf(leaf)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "failed to compute leaf hash")
+	}
+	return hash, nil
+}
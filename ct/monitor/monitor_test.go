@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/jsonclient"
+)
+
+type fakeLogClient struct {
+	getSTH            func(ctx context.Context) (*ct.SignedTreeHead, error)
+	getSTHConsistency func(ctx context.Context, first, second uint64) ([][]byte, error)
+	getProofByHash    func(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error)
+}
+
+func (f *fakeLogClient) GetSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
+	return f.getSTH(ctx)
+}
+
+func (f *fakeLogClient) GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error) {
+	return f.getSTHConsistency(ctx, first, second)
+}
+
+func (f *fakeLogClient) GetProofByHash(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error) {
+	return f.getProofByHash(ctx, hash, treeSize)
+}
+
+type fakeStore struct {
+	mu     sync.Mutex
+	sths   map[string]*StoredSTH
+	saveFn func(logURL string, sth *StoredSTH) error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{sths: make(map[string]*StoredSTH)}
+}
+
+func (s *fakeStore) Load(logURL string) (*StoredSTH, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sths[logURL], nil
+}
+
+func (s *fakeStore) Save(logURL string, sth *StoredSTH) error {
+	if s.saveFn != nil {
+		return s.saveFn(logURL, sth)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sths[logURL] = sth
+	return nil
+}
+
+type fakeHooks struct {
+	mu                  sync.Mutex
+	consistencyFailures int
+	missingInclusions   [][32]byte
+	mmdViolations       [][32]byte
+}
+
+func (h *fakeHooks) STHAge(string, time.Duration) {}
+
+func (h *fakeHooks) ConsistencyFailure(string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.consistencyFailures++
+}
+
+func (h *fakeHooks) MissingInclusion(_ string, leafHash [32]byte, _ time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.missingInclusions = append(h.missingInclusions, leafHash)
+}
+
+func (h *fakeHooks) MMDViolation(_ string, leafHash [32]byte, _ time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mmdViolations = append(h.mmdViolations, leafHash)
+}
+
+func TestMonitorPollOneNoPreviousSTH(t *testing.T) {
+	store := newFakeStore()
+	hooks := &fakeHooks{}
+	m := &Monitor{store: store, hooks: hooks}
+	tgt := &target{
+		url: "https://log.example.com",
+		client: &fakeLogClient{
+			getSTH: func(context.Context) (*ct.SignedTreeHead, error) {
+				return &ct.SignedTreeHead{TreeSize: 100}, nil
+			},
+		},
+	}
+
+	if err := m.pollOne(context.Background(), tgt); err != nil {
+		t.Fatalf("pollOne: %v", err)
+	}
+	if hooks.consistencyFailures != 0 {
+		t.Errorf("expected no consistency check against a nil previous STH, got %d failures", hooks.consistencyFailures)
+	}
+	saved, _ := store.Load(tgt.url)
+	if saved == nil || saved.TreeSize != 100 {
+		t.Errorf("expected STH to be saved with tree size 100, got %+v", saved)
+	}
+}
+
+func TestMonitorPollOneConsistencyFailure(t *testing.T) {
+	store := newFakeStore()
+	store.sths["https://log.example.com"] = &StoredSTH{TreeSize: 50, RootHash: []byte("old-root-hash-000000000000000000")}
+	hooks := &fakeHooks{}
+	m := &Monitor{store: store, hooks: hooks}
+	tgt := &target{
+		url: "https://log.example.com",
+		client: &fakeLogClient{
+			getSTH: func(context.Context) (*ct.SignedTreeHead, error) {
+				return &ct.SignedTreeHead{TreeSize: 100}, nil
+			},
+			getSTHConsistency: func(context.Context, uint64, uint64) ([][]byte, error) {
+				return [][]byte{[]byte("not-a-real-proof")}, nil
+			},
+		},
+	}
+
+	if err := m.pollOne(context.Background(), tgt); err == nil {
+		t.Fatal("expected an error from a bogus consistency proof")
+	}
+	if hooks.consistencyFailures != 1 {
+		t.Errorf("expected ConsistencyFailure to fire once, got %d", hooks.consistencyFailures)
+	}
+	if saved, _ := store.Load(tgt.url); saved.TreeSize != 50 {
+		t.Error("STH should not be saved when its consistency proof fails to verify")
+	}
+}
+
+func TestMonitorCheckPending(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	included := pendingLeaf{leafHash: [32]byte{1}, deadline: past}
+	notYetIncludedPastDeadline := pendingLeaf{leafHash: [32]byte{2}, deadline: past}
+	notYetIncludedBeforeDeadline := pendingLeaf{leafHash: [32]byte{3}, deadline: future}
+	transportErrBeforeDeadline := pendingLeaf{leafHash: [32]byte{4}, deadline: future}
+	transportErrPastDeadline := pendingLeaf{leafHash: [32]byte{5}, deadline: past}
+
+	hooks := &fakeHooks{}
+	m := &Monitor{hooks: hooks}
+	tgt := &target{
+		url: "https://log.example.com",
+		client: &fakeLogClient{
+			getProofByHash: func(_ context.Context, hash []byte, _ uint64) (*ct.GetProofByHashResponse, error) {
+				switch {
+				case hash[0] == included.leafHash[0]:
+					return &ct.GetProofByHashResponse{}, nil
+				case hash[0] == transportErrBeforeDeadline.leafHash[0] || hash[0] == transportErrPastDeadline.leafHash[0]:
+					return nil, errFakeTransport
+				default:
+					return nil, jsonclient.RspError{StatusCode: 404, Err: errFakeTransport}
+				}
+			},
+		},
+		pending: []pendingLeaf{
+			included,
+			notYetIncludedPastDeadline,
+			notYetIncludedBeforeDeadline,
+			transportErrBeforeDeadline,
+			transportErrPastDeadline,
+		},
+	}
+
+	m.checkPending(context.Background(), tgt, 100)
+
+	if len(hooks.mmdViolations) != 1 || hooks.mmdViolations[0] != included.leafHash {
+		t.Errorf("expected exactly one MMDViolation for the already-included leaf, got %v", hooks.mmdViolations)
+	}
+	if len(hooks.missingInclusions) != 1 || hooks.missingInclusions[0] != notYetIncludedPastDeadline.leafHash {
+		t.Errorf("expected exactly one MissingInclusion for the not-found, past-deadline leaf, got %v", hooks.missingInclusions)
+	}
+
+	tgt.mu.Lock()
+	stillPending := tgt.pending
+	tgt.mu.Unlock()
+	if len(stillPending) != 3 {
+		t.Fatalf("expected 3 leaves to remain pending, got %d", len(stillPending))
+	}
+	wantPending := map[[32]byte]bool{
+		notYetIncludedBeforeDeadline.leafHash: true,
+		transportErrBeforeDeadline.leafHash:   true,
+		transportErrPastDeadline.leafHash:     true,
+	}
+	for _, p := range stillPending {
+		if !wantPending[p.leafHash] {
+			t.Errorf("unexpected leaf %v left pending", p.leafHash)
+		}
+	}
+}
+
+// fakeTransportError stands in for a network failure that is neither a
+// jsonclient.RspError nor nil, so isProofNotFound correctly falls through to
+// "unknown, keep retrying".
+type fakeTransportError struct{}
+
+func (fakeTransportError) Error() string { return "connection reset by peer" }
+
+var errFakeTransport = fakeTransportError{}
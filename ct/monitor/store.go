@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// StoredSTH is the subset of a log's Signed Tree Head that a Store needs to
+// persist in order to verify the next STH's consistency with it.
+type StoredSTH struct {
+	TreeSize  uint64 `json:"treeSize"`
+	Timestamp uint64 `json:"timestamp"`
+	RootHash  []byte `json:"rootHash"`
+}
+
+// Store persists the last observed STH for each monitored log, so that
+// consistency can be checked across Monitor restarts.
+type Store interface {
+	// Load returns the last STH observed for logURL, or nil if none has
+	// been recorded yet.
+	Load(logURL string) (*StoredSTH, error)
+	// Save records sth as the last STH observed for logURL.
+	Save(logURL string, sth *StoredSTH) error
+}
+
+// FileStore is a Store that persists one JSON file per log inside dir. It is
+// the default Store used by Monitor when none is configured.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if necessary.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "error creating %s", dir)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements Store.
+func (s *FileStore) Load(logURL string) (*StoredSTH, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := ioutil.ReadFile(s.path(logURL))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error reading STH for %s", logURL)
+	}
+
+	var sth StoredSTH
+	if err := json.Unmarshal(data, &sth); err != nil {
+		return nil, errors.Wrapf(err, "error parsing STH for %s", logURL)
+	}
+	return &sth, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(logURL string, sth *StoredSTH) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(sth)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling STH for %s", logURL)
+	}
+	if err := ioutil.WriteFile(s.path(logURL), data, 0600); err != nil {
+		return errors.Wrapf(err, "error writing STH for %s", logURL)
+	}
+	return nil
+}
+
+// path returns the file a log's STH is stored at, keying on a hash of its
+// URL so that arbitrary log URLs are always safe file names.
+func (s *FileStore) path(logURL string) string {
+	sum := sha256.Sum256([]byte(logURL))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
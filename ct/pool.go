@@ -0,0 +1,213 @@
+package ct
+
+import (
+	"crypto/x509"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// QuorumPolicy defines the minimum number of SCTs, and the minimum number of
+// distinct log operators they must come from, for a Pool submission to be
+// considered successful. For example, MinSCTs: 2, MinOperators: 2 requires
+// at least 2 SCTs from at least 2 different operators, the policy most CT
+// clients use to trust a certificate.
+type QuorumPolicy struct {
+	MinSCTs      int `json:"minSCTs"`
+	MinOperators int `json:"minOperators"`
+}
+
+// Validate validates the quorum policy.
+func (p *QuorumPolicy) Validate() error {
+	switch {
+	case p.MinSCTs <= 0:
+		return errors.New("ct quorum policy minSCTs must be greater than 0")
+	case p.MinOperators <= 0:
+		return errors.New("ct quorum policy minOperators must be greater than 0")
+	default:
+		return nil
+	}
+}
+
+// satisfied reports whether results contains enough successful SCTs, from
+// enough distinct operators, to meet the policy.
+func (p *QuorumPolicy) satisfied(results []*logResult) bool {
+	operators := make(map[string]struct{})
+	scts := 0
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		scts++
+		operators[r.operator] = struct{}{}
+	}
+	return scts >= p.MinSCTs && len(operators) >= p.MinOperators
+}
+
+// PoolConfig is the configuration of a Pool of CT logs.
+type PoolConfig struct {
+	// LogListFile is the path to a JSON file listing the CT logs to submit
+	// precertificates to, in the log-list format published by browser
+	// vendors, e.g. https://www.gstatic.com/ct/log_list/v3/log_list.json.
+	LogListFile string `json:"logListFile"`
+	// Policy is the quorum required for a submission through the pool to
+	// succeed.
+	Policy QuorumPolicy `json:"policy"`
+
+	// Timeout, MaxRetries, InitialBackoff, MaxBackoff, and BreakerThreshold
+	// configure the retry, backoff, and circuit breaker behavior of every
+	// log's client; see the identically named Config fields. A log tripped
+	// out by its breaker fails immediately and, like any other per-log
+	// failure, simply doesn't count towards the quorum.
+	Timeout          time.Duration `json:"timeout,omitempty"`
+	MaxRetries       int           `json:"maxRetries,omitempty"`
+	InitialBackoff   time.Duration `json:"initialBackoff,omitempty"`
+	MaxBackoff       time.Duration `json:"maxBackoff,omitempty"`
+	BreakerThreshold int           `json:"breakerThreshold,omitempty"`
+}
+
+// Validate validates the pool configuration.
+func (c *PoolConfig) Validate() error {
+	if c.LogListFile == "" {
+		return errors.New("ct pool logListFile cannot be empty")
+	}
+	return c.Policy.Validate()
+}
+
+// logMember is a single log participating in a Pool, paired with the client
+// used to talk to it.
+type logMember struct {
+	info   LogInfo
+	client Client
+}
+
+// logResult is the outcome of submitting a precertificate to a single log.
+type logResult struct {
+	url      string
+	operator string
+	sct      *SCT
+	err      error
+}
+
+// Pool manages submission of a precertificate to multiple CT logs and
+// enforces a QuorumPolicy on the SCTs returned.
+type Pool struct {
+	members []*logMember
+	policy  QuorumPolicy
+}
+
+// NewPool creates a Pool from the logs listed in c.LogListFile.
+func NewPool(c PoolConfig) (*Pool, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	list, err := LoadLogList(c.LogListFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := list.Logs()
+	if len(logs) == 0 {
+		return nil, errors.Errorf("%s does not contain any logs", c.LogListFile)
+	}
+
+	members := make([]*logMember, len(logs))
+	for i, info := range logs {
+		keyDER, err := info.keyDER()
+		if err != nil {
+			return nil, err
+		}
+		ci, err := newClient(Config{
+			URI:              info.URL,
+			Timeout:          c.Timeout,
+			MaxRetries:       c.MaxRetries,
+			InitialBackoff:   c.InitialBackoff,
+			MaxBackoff:       c.MaxBackoff,
+			BreakerThreshold: c.BreakerThreshold,
+		}, keyDER)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create client for %s", info.URL)
+		}
+		members[i] = &logMember{info: info, client: ci}
+	}
+
+	return &Pool{
+		members: members,
+		policy:  c.Policy,
+	}, nil
+}
+
+// eligible returns the logs willing to accept a certificate with the given
+// NotAfter date.
+func (p *Pool) eligible(notAfter time.Time) []*logMember {
+	var eligible []*logMember
+	for _, m := range p.members {
+		if m.info.TemporalInterval == nil || m.info.TemporalInterval.contains(notAfter) {
+			eligible = append(eligible, m)
+		}
+	}
+	return eligible
+}
+
+// GetSCTs submits the precertificate to every eligible log in the pool in
+// parallel and returns the SCTs from the logs that succeeded. An error is
+// returned unless the quorum policy is met; per-log failures are logged but
+// do not by themselves fail the call.
+func (p *Pool) GetSCTs(chain ...*x509.Certificate) ([]*SCT, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("chain cannot be empty")
+	}
+
+	members := p.eligible(chain[0].NotAfter)
+	if len(members) == 0 {
+		return nil, errors.New("no configured CT log accepts a certificate with this NotAfter date")
+	}
+
+	results := make([]*logResult, len(members))
+	var wg sync.WaitGroup
+	wg.Add(len(members))
+	for i, m := range members {
+		go func(i int, m *logMember) {
+			defer wg.Done()
+			sct, err := m.client.GetSCTs(chain...)
+			results[i] = &logResult{url: m.info.URL, operator: m.info.Operator, sct: sct, err: err}
+		}(i, m)
+	}
+	wg.Wait()
+
+	var scts []*SCT
+	for _, r := range results {
+		if r.err != nil {
+			log.Printf("ct: log %s (%s) failed: %v", r.url, r.operator, r.err)
+			continue
+		}
+		log.Printf("ct: log %s (%s) returned an SCT", r.url, r.operator)
+		scts = append(scts, r.sct)
+	}
+
+	if !p.policy.satisfied(results) {
+		return scts, errors.Errorf("ct: quorum not met: got %d SCTs from %d eligible logs, policy requires >=%d SCTs from >=%d operators",
+			len(scts), len(members), p.policy.MinSCTs, p.policy.MinOperators)
+	}
+	return scts, nil
+}
+
+// SubmitToLogs submits the final certificate to every log in the pool,
+// logging but not failing on individual log errors.
+func (p *Pool) SubmitToLogs(chain ...*x509.Certificate) error {
+	var wg sync.WaitGroup
+	wg.Add(len(p.members))
+	for _, m := range p.members {
+		go func(m *logMember) {
+			defer wg.Done()
+			if err := m.client.SubmitToLogs(chain...); err != nil {
+				log.Printf("ct: failed to submit certificate to %s (%s): %v", m.info.URL, m.info.Operator, err)
+			}
+		}(m)
+	}
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,81 @@
+package ct
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestQuorumPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  QuorumPolicy
+		wantErr bool
+	}{
+		{"valid", QuorumPolicy{MinSCTs: 2, MinOperators: 2}, false},
+		{"zero minSCTs", QuorumPolicy{MinSCTs: 0, MinOperators: 1}, true},
+		{"zero minOperators", QuorumPolicy{MinSCTs: 1, MinOperators: 0}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.wantErr && err == nil {
+				t.Error("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestQuorumPolicySatisfied(t *testing.T) {
+	policy := QuorumPolicy{MinSCTs: 2, MinOperators: 2}
+
+	tests := []struct {
+		name    string
+		results []*logResult
+		want    bool
+	}{
+		{
+			name: "meets policy",
+			results: []*logResult{
+				{operator: "Google", sct: &SCT{}},
+				{operator: "Cloudflare", sct: &SCT{}},
+			},
+			want: true,
+		},
+		{
+			name: "enough SCTs but single operator",
+			results: []*logResult{
+				{operator: "Google", sct: &SCT{}},
+				{operator: "Google", sct: &SCT{}},
+			},
+			want: false,
+		},
+		{
+			name: "not enough SCTs",
+			results: []*logResult{
+				{operator: "Google", sct: &SCT{}},
+				{operator: "Cloudflare", err: errors.New("log unavailable")},
+			},
+			want: false,
+		},
+		{
+			name: "failures don't count towards either threshold",
+			results: []*logResult{
+				{operator: "Google", sct: &SCT{}},
+				{operator: "Cloudflare", sct: &SCT{}},
+				{operator: "DigiCert", err: errors.New("circuit open")},
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.satisfied(tc.results); got != tc.want {
+				t.Errorf("satisfied() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package ct
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/google/certificate-transparency-go/jsonclient"
+)
+
+// isTransient reports whether err is worth retrying: connection errors,
+// timeouts, and 5xx responses from the log are transient, while a 4xx
+// response is treated as permanent since retrying it will fail identically.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rspErr jsonclient.RspError
+	if errors.As(err, &rspErr) {
+		return rspErr.StatusCode == 0 || rspErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoff computes the delay before retry attempt n (0-based), as an
+// exponential backoff from initial, capped at max, with full jitter.
+func backoff(n int, initial, max time.Duration) time.Duration {
+	d := initial << uint(n)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// withRetry calls fn, retrying up to maxRetries times on transient errors
+// with exponential backoff between attempts. It returns the last error seen
+// if every attempt fails, or immediately on a permanent error.
+func withRetry(ctx context.Context, maxRetries int, initial, max time.Duration, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= maxRetries || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff(attempt, initial, max)):
+		}
+	}
+}
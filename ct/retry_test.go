@@ -0,0 +1,95 @@
+package ct
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/certificate-transparency-go/jsonclient"
+	"github.com/pkg/errors"
+)
+
+type fakeNetError struct{ error }
+
+func (fakeNetError) Timeout() bool   { return true }
+func (fakeNetError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"4xx is permanent", jsonclient.RspError{StatusCode: 404, Err: errors.New("not found")}, false},
+		{"5xx is transient", jsonclient.RspError{StatusCode: 503, Err: errors.New("unavailable")}, true},
+		{"rsp error without status code is transient", jsonclient.RspError{Err: errors.New("bad response")}, true},
+		{"net error is transient", fakeNetError{errors.New("connection refused")}, true},
+		{"deadline exceeded is transient", context.DeadlineExceeded, true},
+		{"other errors are permanent", errors.New("boom"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isTransient(tc.err); got != tc.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	max := 2 * time.Second
+	for n := 0; n < 10; n++ {
+		d := backoff(n, 100*time.Millisecond, max)
+		if d < 0 || d > max {
+			t.Errorf("backoff(%d) = %v, want within [0, %v]", n, d, max)
+		}
+	}
+}
+
+func TestWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), 3, time.Millisecond, 5*time.Millisecond, func() error {
+		attempts++
+		if attempts < 3 {
+			return jsonclient.RspError{StatusCode: 503, Err: errors.New("unavailable")}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestWithRetryStopsOnPermanentError(t *testing.T) {
+	attempts := 0
+	permanent := jsonclient.RspError{StatusCode: 400, Err: errors.New("bad request")}
+	err := withRetry(context.Background(), 3, time.Millisecond, 5*time.Millisecond, func() error {
+		attempts++
+		return permanent
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt for a permanent error, got %d", attempts)
+	}
+}
+
+func TestWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	transient := jsonclient.RspError{StatusCode: 503, Err: errors.New("unavailable")}
+	err := withRetry(context.Background(), 2, time.Millisecond, 5*time.Millisecond, func() error {
+		attempts++
+		return transient
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts (1 initial + 2 retries), got %d", attempts)
+	}
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+)
+
+// Matcher decides whether a certificate parsed out of a log entry should be
+// reported as a match by Scanner.Scan.
+type Matcher interface {
+	Matches(cert *ctx509.Certificate) bool
+}
+
+// MatcherFunc adapts a plain function to a Matcher, for arbitrary predicates
+// that don't warrant their own type.
+type MatcherFunc func(cert *ctx509.Certificate) bool
+
+// Matches implements Matcher.
+func (f MatcherFunc) Matches(cert *ctx509.Certificate) bool {
+	return f(cert)
+}
+
+// MatchSubjectAlternativeName returns a Matcher that matches certificates
+// listing name as a DNS subject alternative name.
+func MatchSubjectAlternativeName(name string) Matcher {
+	return MatcherFunc(func(cert *ctx509.Certificate) bool {
+		for _, san := range cert.DNSNames {
+			if san == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// MatchIssuerDN returns a Matcher that matches certificates whose issuer
+// distinguished name equals dn.
+func MatchIssuerDN(dn string) Matcher {
+	return MatcherFunc(func(cert *ctx509.Certificate) bool {
+		return cert.Issuer.String() == dn
+	})
+}
+
+// MatchSPKIHash returns a Matcher that matches certificates whose subject
+// public key, SHA-256 hashed, equals hash.
+func MatchSPKIHash(hash []byte) Matcher {
+	return MatcherFunc(func(cert *ctx509.Certificate) bool {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return bytes.Equal(sum[:], hash)
+	})
+}
+
+// MatchAuthorityKeyID returns a Matcher that matches certificates whose
+// Authority Key Identifier extension equals keyID, i.e. certificates issued
+// by the holder of that key. keyID is compared as the raw AKID extension
+// bytes (conventionally a SHA-1 digest of the issuer's public key per RFC
+// 5280), not a SHA-256 SPKI hash as computed by MatchSPKIHash.
+func MatchAuthorityKeyID(keyID []byte) Matcher {
+	return MatcherFunc(func(cert *ctx509.Certificate) bool {
+		return bytes.Equal(cert.AuthorityKeyId, keyID)
+	})
+}
@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+)
+
+func TestMatchSubjectAlternativeName(t *testing.T) {
+	cert := &ctx509.Certificate{DNSNames: []string{"example.com", "www.example.com"}}
+	m := MatchSubjectAlternativeName("www.example.com")
+	if !m.Matches(cert) {
+		t.Error("expected match on www.example.com")
+	}
+	if MatchSubjectAlternativeName("other.com").Matches(cert) {
+		t.Error("did not expect match on other.com")
+	}
+}
+
+func TestMatchIssuerDN(t *testing.T) {
+	cert := &ctx509.Certificate{}
+	cert.Issuer.CommonName = "Test CA"
+	dn := cert.Issuer.String()
+
+	if !MatchIssuerDN(dn).Matches(cert) {
+		t.Error("expected match on issuer DN")
+	}
+	if MatchIssuerDN("CN=Someone Else").Matches(cert) {
+		t.Error("did not expect match on different issuer DN")
+	}
+}
+
+func TestMatchSPKIHash(t *testing.T) {
+	spki := []byte("fake subject public key info")
+	sum := sha256.Sum256(spki)
+	cert := &ctx509.Certificate{RawSubjectPublicKeyInfo: spki}
+
+	if !MatchSPKIHash(sum[:]).Matches(cert) {
+		t.Error("expected match on SPKI hash")
+	}
+	if MatchSPKIHash([]byte("not the hash")).Matches(cert) {
+		t.Error("did not expect match on wrong hash")
+	}
+}
+
+func TestMatchAuthorityKeyID(t *testing.T) {
+	akid := []byte{0x01, 0x02, 0x03, 0x04}
+	cert := &ctx509.Certificate{AuthorityKeyId: akid}
+
+	if !MatchAuthorityKeyID(akid).Matches(cert) {
+		t.Error("expected match on authority key id")
+	}
+	if MatchAuthorityKeyID([]byte{0xff}).Matches(cert) {
+		t.Error("did not expect match on wrong authority key id")
+	}
+}
@@ -0,0 +1,169 @@
+// Package scanner walks a certificate transparency log looking for
+// certificates that match a caller-supplied predicate, so CA operators can
+// audit which of their issued certificates actually made it into a log.
+// It is modeled after the upstream certificate-transparency-go
+// scanner.Scanner, scoped down to that read-only audit use case.
+package scanner
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+
+	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/client"
+	"github.com/google/certificate-transparency-go/jsonclient"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+	"github.com/pkg/errors"
+)
+
+const defaultBatchSize = 1000
+
+type entryClient interface {
+	GetSTH(ctx context.Context) (*ct.SignedTreeHead, error)
+	GetEntries(ctx context.Context, start, end int64) ([]ct.LogEntry, error)
+}
+
+// Match is a single log entry that satisfied a Matcher.
+type Match struct {
+	Index     uint64
+	Cert      *ctx509.Certificate
+	IsPrecert bool
+}
+
+// ScannerOptions configures a single Scan call.
+type ScannerOptions struct {
+	// Matcher selects which certificates are reported as matches. Required.
+	Matcher Matcher
+	// BatchSize is the number of entries requested per GetEntries call.
+	// Defaults to 1000.
+	BatchSize int
+	// ParallelFetchers is the number of GetEntries calls issued
+	// concurrently. Defaults to 1.
+	ParallelFetchers int
+}
+
+// Scanner walks the entries of a single CT log.
+type Scanner struct {
+	url    string
+	client entryClient
+}
+
+// New creates a Scanner for the log at uri.
+func New(uri string) (*Scanner, error) {
+	c, err := client.New(uri, &http.Client{}, jsonclient.Options{
+		UserAgent: "smallstep certificates scanner",
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client to %s", uri)
+	}
+	return &Scanner{url: uri, client: c}, nil
+}
+
+// Scan walks entries [start, end) of the log in batches of opts.BatchSize,
+// fetched by opts.ParallelFetchers workers, and returns a channel of the
+// entries that satisfy opts.Matcher. The channel is closed once every batch
+// has been fetched or ctx is canceled. A failure to fetch or parse one batch
+// is logged and skipped; it does not stop the scan.
+func (s *Scanner) Scan(ctx context.Context, start, end uint64, opts ScannerOptions) (<-chan Match, error) {
+	if opts.Matcher == nil {
+		return nil, errors.New("scanner: opts.Matcher cannot be nil")
+	}
+	if end < start {
+		return nil, errors.New("scanner: end cannot be before start")
+	}
+
+	batchSize := uint64(opts.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	workers := opts.ParallelFetchers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	type batch struct{ start, end uint64 }
+	batches := make(chan batch)
+	matches := make(chan Match)
+
+	go func() {
+		defer close(batches)
+		for i := start; i < end; i += batchSize {
+			j := i + batchSize
+			if j > end {
+				j = end
+			}
+			select {
+			case batches <- batch{start: i, end: j - 1}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				s.scanBatch(ctx, b.start, b.end, opts.Matcher, matches)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(matches)
+	}()
+
+	return matches, nil
+}
+
+func (s *Scanner) scanBatch(ctx context.Context, start, end uint64, matcher Matcher, matches chan<- Match) {
+	entries, err := s.client.GetEntries(ctx, int64(start), int64(end))
+	if err != nil {
+		log.Printf("ct scanner: %s: GetEntries(%d, %d): %v", s.url, start, end, err)
+		return
+	}
+
+	for i, entry := range entries {
+		var cert *ctx509.Certificate
+		isPrecert := false
+		switch {
+		case entry.X509Cert != nil:
+			cert = entry.X509Cert
+		case entry.Precert != nil:
+			cert = entry.Precert.TBSCertificate
+			isPrecert = true
+		default:
+			continue
+		}
+
+		if !matcher.Matches(cert) {
+			continue
+		}
+
+		select {
+		case matches <- Match{Index: start + uint64(i), Cert: cert, IsPrecert: isPrecert}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// FindIssuedBy scans the whole log for certificates whose Authority Key
+// Identifier equals issuerKeyID (the CA's own Subject Key Identifier), i.e.
+// certificates issued by the holder of that key. It's the high-level entry
+// point CA operators run to confirm that certificates they issued actually
+// made it into the log.
+func (s *Scanner) FindIssuedBy(ctx context.Context, issuerKeyID []byte) (<-chan Match, error) {
+	sth, err := s.client.GetSTH(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get STH from %s", s.url)
+	}
+	return s.Scan(ctx, 0, sth.TreeSize, ScannerOptions{
+		Matcher: MatchAuthorityKeyID(issuerKeyID),
+	})
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	ct "github.com/google/certificate-transparency-go"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+)
+
+type fakeEntryClient struct {
+	sth        *ct.SignedTreeHead
+	sthErr     error
+	getEntries func(start, end int64) ([]ct.LogEntry, error)
+}
+
+func (f *fakeEntryClient) GetSTH(context.Context) (*ct.SignedTreeHead, error) {
+	return f.sth, f.sthErr
+}
+
+func (f *fakeEntryClient) GetEntries(_ context.Context, start, end int64) ([]ct.LogEntry, error) {
+	return f.getEntries(start, end)
+}
+
+func certEntry(index int64, dnsName string) ct.LogEntry {
+	return ct.LogEntry{
+		Index:    index,
+		X509Cert: &ctx509.Certificate{DNSNames: []string{dnsName}},
+	}
+}
+
+func TestScannerScan(t *testing.T) {
+	entries := []ct.LogEntry{
+		certEntry(0, "match.example.com"),
+		certEntry(1, "other.example.com"),
+		certEntry(2, "match.example.com"),
+	}
+	client := &fakeEntryClient{
+		getEntries: func(start, end int64) ([]ct.LogEntry, error) {
+			return entries[start : end+1], nil
+		},
+	}
+	s := &Scanner{url: "https://log.example.com", client: client}
+
+	matches, err := s.Scan(context.Background(), 0, 3, ScannerOptions{
+		Matcher: MatchSubjectAlternativeName("match.example.com"),
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	var got []uint64
+	for m := range matches {
+		got = append(got, m.Index)
+	}
+	if len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("expected matches at indices [0 2], got %v", got)
+	}
+}
+
+func TestScannerScanBatchesAndSkipsFailures(t *testing.T) {
+	var requested [][2]int64
+	client := &fakeEntryClient{
+		getEntries: func(start, end int64) ([]ct.LogEntry, error) {
+			requested = append(requested, [2]int64{start, end})
+			if start == 2 {
+				return nil, errFakeGetEntries
+			}
+			return []ct.LogEntry{certEntry(start, "match.example.com")}, nil
+		},
+	}
+	s := &Scanner{url: "https://log.example.com", client: client}
+
+	matches, err := s.Scan(context.Background(), 0, 4, ScannerOptions{
+		Matcher:   MatchSubjectAlternativeName("match.example.com"),
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	var got []uint64
+	for m := range matches {
+		got = append(got, m.Index)
+	}
+	if len(got) != 1 || got[0] != 0 {
+		t.Errorf("expected the failed batch [2,4) to be skipped, got matches %v", got)
+	}
+	if len(requested) != 2 {
+		t.Errorf("expected 2 batches of size 2 to be requested, got %v", requested)
+	}
+}
+
+func TestScannerScanRejectsNilMatcherOrInvertedRange(t *testing.T) {
+	s := &Scanner{url: "https://log.example.com", client: &fakeEntryClient{}}
+
+	if _, err := s.Scan(context.Background(), 0, 1, ScannerOptions{}); err == nil {
+		t.Error("expected an error with a nil Matcher")
+	}
+	if _, err := s.Scan(context.Background(), 5, 1, ScannerOptions{Matcher: MatchIssuerDN("")}); err == nil {
+		t.Error("expected an error when end is before start")
+	}
+}
+
+func TestScannerFindIssuedBy(t *testing.T) {
+	akid := []byte{0xaa, 0xbb}
+	client := &fakeEntryClient{
+		sth: &ct.SignedTreeHead{TreeSize: 2},
+		getEntries: func(start, end int64) ([]ct.LogEntry, error) {
+			return []ct.LogEntry{
+				{Index: 0, X509Cert: &ctx509.Certificate{AuthorityKeyId: akid}},
+				{Index: 1, X509Cert: &ctx509.Certificate{AuthorityKeyId: []byte{0xff}}},
+			}[start : end+1], nil
+		},
+	}
+	s := &Scanner{url: "https://log.example.com", client: client}
+
+	matches, err := s.FindIssuedBy(context.Background(), akid)
+	if err != nil {
+		t.Fatalf("FindIssuedBy: %v", err)
+	}
+
+	var got []uint64
+	for m := range matches {
+		got = append(got, m.Index)
+	}
+	if len(got) != 1 || got[0] != 0 {
+		t.Errorf("expected only index 0 to match the issuer key id, got %v", got)
+	}
+}
+
+var errFakeGetEntries = errFake("GetEntries failed")
+
+type errFake string
+
+func (e errFake) Error() string { return string(e) }